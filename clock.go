@@ -0,0 +1,63 @@
+// Copyright 2022 The concurrentloop Authors. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package concurrentloop
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// Clock abstracts time so `WithRandomDelayTime` and `WithRetry` can be
+// driven by a fake implementation in tests, advancing instantly instead of
+// sleeping in real time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Sleep pauses the calling goroutine for at least `d`.
+	Sleep(d time.Duration)
+
+	// NewTicker returns a ticker that sends on its channel every `d`.
+	NewTicker(d time.Duration) *time.Ticker
+}
+
+// realClock is the default `Clock`, backed by the standard library.
+type realClock struct{}
+
+// Now implements `Clock`.
+func (realClock) Now() time.Time { return time.Now() }
+
+// Sleep implements `Clock`.
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// NewTicker implements `Clock`.
+func (realClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+//////
+// Helpers.
+//////
+
+// clockOrDefault returns `c`, or the default `realClock` if `c` is nil.
+func clockOrDefault(c Clock) Clock {
+	if c == nil {
+		return realClock{}
+	}
+
+	return c
+}
+
+// randSourceOrDefault returns `src`, or a non-deterministic, non-seedable
+// `math/rand/v2`-backed source if `src` is nil.
+func randSourceOrDefault(src rand.Source) rand.Source {
+	if src == nil {
+		return rand.NewPCG(rand.Uint64(), rand.Uint64())
+	}
+
+	return src
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 The concurrentloop Authors. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+//
+//nolint:exhaustruct
+package concurrentloop
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a `Clock` that advances instantly: `Sleep` returns right away,
+// but still records how long it was asked to sleep for, so tests can assert
+// on the schedule without waiting on it in real time.
+type fakeClock struct {
+	mu    sync.Mutex
+	slept []time.Duration
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.slept = append(c.slept, d)
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+func (c *fakeClock) sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]time.Duration(nil), c.slept...)
+}
+
+func TestWithRandSource_deterministic(t *testing.T) {
+	run := func() time.Duration {
+		clock := &fakeClock{now: time.Now()}
+
+		_, _ = Map(context.Background(), []int{1}, func(ctx context.Context, i int) (int, error) {
+			return 0, errors.New("transient")
+		}, WithRetry(2, time.Second, 0, 1, 0.5), WithClock(clock), WithRandSource(rand.NewPCG(42, 42)))
+
+		sleeps := clock.sleeps()
+
+		return sleeps[len(sleeps)-1]
+	}
+
+	assert.Equal(t, run(), run())
+}
@@ -6,35 +6,14 @@ package concurrentloop
 
 import (
 	"context"
-	"strings"
+	"fmt"
+	"sync"
 )
 
-// Errors is a slice of errors.
-type Errors []error
-
-// Error returns a string representation of the combined errors in the `Errors`
-// slice, separated by commas. This method satisfies the `error` interface.
-//
-//nolint:prealloc
-func (e Errors) Error() string {
-	var errs []string
-
-	for _, err := range e {
-		errs = append(errs, err.Error())
-	}
-
-	return strings.Join(errs, ", ")
-}
-
-// ResultCh receives the result from the channel.
-type ResultCh[T any] struct {
-	Output T
-	Error  error
-}
-
-// Func is the type of the function that will be executed concurrently for each
-// element in a slice of type `T`. The function takes a `context.Context` and a
-// value of type `T`, and returns a value of type `Result` and an error value.
+// RunFunc is the type of the function that will be executed concurrently for
+// each element in a slice of type `T`. The function takes a `context.Context`
+// and a value of type `T`, and returns a value of type `Result` and an error
+// value.
 //
 // Example usage:
 //
@@ -49,9 +28,9 @@ type ResultCh[T any] struct {
 //	...
 //
 //	results, errs := Run(ctx, sl, myFunc)
-type Func[T any, Result any] func(context.Context, T) (Result, error)
+type RunFunc[T any, Result any] func(context.Context, T) (Result, error)
 
-// Run calls the `Func` concurrently on each element of `sl`, and returns the
+// Run calls `f` concurrently on each element of `sl`, and returns the
 // results and any errors that occurred. The function blocks until all
 // executions have completed.
 //
@@ -66,9 +45,9 @@ type Func[T any, Result any] func(context.Context, T) (Result, error)
 //
 //	// Process the results.
 //	// ...
-func Run[T any, Result any](ctx context.Context, sl []T, f Func[T, Result]) ([]Result, Errors) {
+func Run[T any, Result any](ctx context.Context, sl []T, f RunFunc[T, Result], opts ...Func) ([]Result, Errors) {
 	// Calls runCh, and closes the channel.
-	resultsCh := RunCh(ctx, sl, f)
+	resultsCh := RunCh(ctx, sl, f, opts...)
 	defer close(resultsCh)
 
 	var (
@@ -89,7 +68,7 @@ func Run[T any, Result any](ctx context.Context, sl []T, f Func[T, Result]) ([]R
 	return results, errs
 }
 
-// RunCh calls the `Func` concurrently on each element of `sl`, and returns a
+// RunCh calls `f` concurrently on each element of `sl`, and returns a
 // channel that receives the results. The results are returned as a `resultCh`
 // struct, which contains the output value and an error value if the function
 // call failed.
@@ -123,19 +102,68 @@ func Run[T any, Result any](ctx context.Context, sl []T, f Func[T, Result]) ([]R
 //	// ...
 //
 // NOTE: It's the caller's responsibility to close the channel.
-func RunCh[T any, Result any](ctx context.Context, sl []T, f Func[T, Result]) chan ResultCh[Result] {
+func RunCh[T any, Result any](ctx context.Context, sl []T, f RunFunc[T, Result], opts ...Func) chan ResultCh[Result] {
+	o := Option{PanicAsError: true}
+
+	for _, opt := range opts {
+		o = opt(o)
+	}
+
+	retry := o.retryPolicy()
+
+	// When fail-fast is enabled, the first error returned by `f` cancels this
+	// context, which is passed down to every in-flight call of `f`.
+	ctx, cancel := context.WithCancel(ctx)
+
 	// Create a channel to receive the results.
 	resultsCh := make(chan ResultCh[Result])
 
+	var wg sync.WaitGroup
+
 	// Concurrently call, and send the result to the channel.
 	for _, t := range sl {
 		t := t
 
+		wg.Add(1)
+
 		go func(sl []T) {
-			result, err := f(ctx, t)
+			defer wg.Done()
+
+			if o.PanicAsError {
+				defer func() {
+					if r := recover(); r != nil {
+						err, stack := newPanicError(fmt.Sprintf("%v", t), r)
+
+						if o.FailFast {
+							cancel()
+						}
+
+						resultsCh <- ResultCh[Result]{Error: err, Panic: r, Stack: stack}
+					}
+				}()
+			}
+
+			var result Result
+
+			err := retry.run(ctx, func() error {
+				var ferr error
+
+				result, ferr = f(ctx, t)
+
+				return ferr
+			})
+			if err != nil && o.FailFast {
+				cancel()
+			}
+
 			resultsCh <- ResultCh[Result]{Output: result, Error: err}
 		}(sl)
 	}
 
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+
 	return resultsCh
 }
@@ -11,7 +11,7 @@ import (
 	"testing"
 )
 
-func TestNew_ConcurrentProcessing(t *testing.T) {
+func TestNew_ConcurrentProcessing_Run(t *testing.T) {
 	sl1 := []int{1, 2, 3, 4, 5}
 	sl2 := []string{"a", "b", "c", "d", "e"}
 	sl3 := []float64{1.1, 2.2, 3.3, 4.4, 5.5}
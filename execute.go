@@ -6,6 +6,8 @@ package concurrentloop
 
 import (
 	"context"
+	"fmt"
+	"sync"
 )
 
 //////
@@ -24,9 +26,9 @@ type ExecuteFunc[T any] func(context.Context) (T, error)
 
 // Execute calls the `fns` concurrently, and returns the results and any errors
 // that occurred. The function blocks until all executions have completed.
-func Execute[T any](ctx context.Context, fns []ExecuteFunc[T]) ([]T, Errors) {
+func Execute[T any](ctx context.Context, fns []ExecuteFunc[T], opts ...Func) ([]T, Errors) {
 	// Calls runCh, and closes the channel.
-	resultsCh := ExecuteCh(ctx, fns)
+	resultsCh := ExecuteCh(ctx, fns, opts...)
 	defer close(resultsCh)
 
 	var (
@@ -50,15 +52,60 @@ func Execute[T any](ctx context.Context, fns []ExecuteFunc[T]) ([]T, Errors) {
 // ExecuteCh calls the `fns` concurrently.
 //
 // NOTE: It's the caller's responsibility to close the channel.
-func ExecuteCh[T any](ctx context.Context, fns []ExecuteFunc[T]) chan ResultCh[T] {
+func ExecuteCh[T any](ctx context.Context, fns []ExecuteFunc[T], opts ...Func) chan ResultCh[T] {
+	o := Option{PanicAsError: true}
+
+	for _, opt := range opts {
+		o = opt(o)
+	}
+
+	retry := o.retryPolicy()
+
+	// When fail-fast is enabled, the first error returned by a function in
+	// `fns` cancels this context, which is passed down to every in-flight
+	// call.
+	ctx, cancel := context.WithCancel(ctx)
+
 	resultsCh := make(chan ResultCh[T])
 
-	for _, fn := range fns {
-		fn := fn
+	var wg sync.WaitGroup
+
+	for i, fn := range fns {
+		i, fn := i, fn
+
+		wg.Add(1)
 
 		go func(fn ExecuteFunc[T]) {
-			result, err := fn(ctx)
+			defer wg.Done()
+
+			if o.PanicAsError {
+				defer func() {
+					if r := recover(); r != nil {
+						err, stack := newPanicError(fmt.Sprintf("fns[%d]", i), r)
+
+						if o.FailFast {
+							cancel()
+						}
+
+						resultsCh <- ResultCh[T]{Error: err, Panic: r, Stack: stack}
+					}
+				}()
+			}
+
+			var result T
+
+			err := retry.run(ctx, func() error {
+				var ferr error
+
+				result, ferr = fn(ctx)
+
+				return ferr
+			})
 			if err != nil {
+				if o.FailFast {
+					cancel()
+				}
+
 				resultsCh <- ResultCh[T]{Output: result, Error: err}
 
 				return
@@ -68,5 +115,10 @@ func ExecuteCh[T any](ctx context.Context, fns []ExecuteFunc[T]) chan ResultCh[T
 		}(fn)
 	}
 
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+
 	return resultsCh
 }
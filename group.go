@@ -0,0 +1,118 @@
+// Copyright 2022 The concurrentloop Authors. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package concurrentloop
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// group coordinates a batch of concurrently-running workers sharing a
+// `semaphore.Weighted`-backed concurrency limit. It centralizes the error
+// bookkeeping and, when fail-fast is enabled, the errgroup-style "first
+// error cancels the rest" semantics that used to be duplicated as ad-hoc
+// `errs`/`errMutex` bookkeeping across `Map` and `MapM`. Counting results
+// for `WithLimit` is left to the callers.
+type group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	sem *semaphore.Weighted
+	wg  sync.WaitGroup
+
+	failFast bool
+
+	errMu sync.Mutex
+	errs  []error
+}
+
+//////
+// Methods.
+//////
+
+// Context returns the context workers, and the scheduling loop, should use.
+// It's cancelled once fail-fast triggers, or when the parent context given
+// to `newGroup` is done.
+func (g *group) Context() context.Context {
+	return g.ctx
+}
+
+// Acquire blocks until a worker slot is free, or `g`'s context is done.
+func (g *group) Acquire() error {
+	return g.sem.Acquire(g.ctx, 1)
+}
+
+// Release frees up a worker slot previously reserved with `Acquire`.
+func (g *group) Release() {
+	g.sem.Release(1)
+}
+
+// Go runs `fn` as a tracked worker. The caller must have already reserved a
+// slot via `Acquire`; `Go` releases it once `fn` returns. Any error returned
+// by `fn` is recorded, and, if fail-fast is enabled, cancels `g`'s context so
+// no further slots are acquired.
+func (g *group) Go(fn func() error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+		defer g.Release()
+
+		if err := fn(); err != nil {
+			g.AddErr(err)
+		}
+	}()
+}
+
+// AddErr records `err`, triggering fail-fast cancellation if enabled. It's
+// exported to the package so callers can report errors - e.g. a recovered
+// panic - that don't naturally flow through `Go`'s return value.
+func (g *group) AddErr(err error) {
+	g.errMu.Lock()
+	defer g.errMu.Unlock()
+
+	g.errs = append(g.errs, err)
+
+	if g.failFast {
+		g.cancel()
+	}
+}
+
+// Wait blocks until every worker started with `Go` has returned, releases
+// `g`'s resources, and returns the accumulated errors, if any.
+func (g *group) Wait() Errors {
+	g.wg.Wait()
+	g.cancel()
+
+	if len(g.errs) == 0 {
+		return nil
+	}
+
+	return g.errs
+}
+
+//////
+// Exported functionalities.
+//////
+
+// newGroup returns a group limited to `batchSize` concurrent workers,
+// derived from `ctx`. When `failFast` is true, the first error reported via
+// `AddErr` cancels the group's context.
+func newGroup(ctx context.Context, batchSize int, failFast bool) *group {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &group{
+		ctx:      ctx,
+		cancel:   cancel,
+		sem:      semaphore.NewWeighted(int64(batchSize)),
+		failFast: failFast,
+	}
+}
@@ -7,6 +7,7 @@ package concurrentloop
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"reflect"
 	"runtime"
 	"sync"
@@ -14,8 +15,6 @@ import (
 	"time"
 
 	"github.com/thalesfsp/customerror"
-	"github.com/thalesfsp/randomness"
-	"golang.org/x/sync/semaphore"
 )
 
 //////
@@ -65,6 +64,11 @@ func RemoveZeroValues[T any](removeZeroValues bool, results []T) []T {
 // with the results. The order of the results matches the order of the input
 // slice.
 //
+// If `WithFailFast(true)` is set, the first error returned by `f` cancels the
+// context passed to in-flight and not-yet-started workers, and `Map` returns
+// as soon as the in-flight workers finish, along with the partial results
+// collected so far.
+//
 // If any of the operations are cancelled by the context, the function will
 // panic.
 //
@@ -95,44 +99,33 @@ func Map[T any, Result any](
 	o := Option{
 		BatchSize:        runtime.NumCPU(),
 		RemoveZeroValues: true,
+		PanicAsError:     true,
 	}
 
 	for _, opt := range opts {
 		o = opt(o)
 	}
 
-	sem := semaphore.NewWeighted(int64(o.BatchSize))
+	g := newGroup(ctx, o.BatchSize, o.FailFast)
+
+	retry := o.retryPolicy()
 
-	wg := &sync.WaitGroup{}
+	clock := clockOrDefault(o.Clock)
+	random := rand.New(randSourceOrDefault(o.RandSource))
 
 	results := make([]Result, len(items))
 
-	var (
-		errs     []error
-		errMutex sync.Mutex
-
-		resultTracker uint64 = 1
-	)
+	var resultTracker uint64 = 1
 
 	var rN int64
 
 	if o.RandomDelayTimeMin != 0 || o.RandomDelayTimeMax != 0 && o.RandomDelayTimeMin < o.RandomDelayTimeMax && o.RandomDelayTimeDuration != 0 {
-		r, err := randomness.New(o.RandomDelayTimeMin, o.RandomDelayTimeMax, 3, false)
-		if err != nil {
-			return nil, []error{err}
-		}
-
-		n, err := r.Generate()
-		if err != nil {
-			return nil, []error{err}
-		}
-
-		rN = n
+		rN = int64(o.RandomDelayTimeMin) + random.Int64N(int64(o.RandomDelayTimeMax-o.RandomDelayTimeMin))
 	}
 
 	for i := range items {
 		if rN != 0 {
-			time.Sleep(time.Duration(rN) * o.RandomDelayTimeDuration)
+			clock.Sleep(time.Duration(rN) * o.RandomDelayTimeDuration)
 		}
 
 		if o.Limit > 0 {
@@ -141,77 +134,88 @@ func Map[T any, Result any](
 			}
 		}
 
-		if ctx.Err() != nil {
-			errs = append(errs, customerror.New(fmt.Sprintf(`context errored before mapping "%v"`, items[i])))
+		if g.Context().Err() != nil {
+			g.AddErr(customerror.New(fmt.Sprintf(`context errored before mapping "%v"`, items[i])))
 
-			return RemoveZeroValues(o.RemoveZeroValues, results), errs
+			break
 		}
 
-		if err := sem.Acquire(ctx, 1); err != nil {
-			errs = append(errs, customerror.New(fmt.Sprintf(`context timeout before mapping "%v"`, items[i])))
+		if err := g.Acquire(); err != nil {
+			g.AddErr(customerror.New(fmt.Sprintf(`context timeout before mapping "%v"`, items[i])))
 
-			return RemoveZeroValues(o.RemoveZeroValues, results), errs
+			break
 		}
 
-		wg.Add(1)
+		i := i
 
-		go func(i int) {
-			defer sem.Release(1)
-			defer wg.Done()
+		g.Go(func() error {
+			if o.PanicAsError {
+				defer func() {
+					if r := recover(); r != nil {
+						err, _ := newPanicError(fmt.Sprintf("%v", items[i]), r)
 
-			res, err := f(ctx, items[i])
-			if err != nil {
-				errMutex.Lock()
-				defer errMutex.Unlock()
+						g.AddErr(err)
+					}
+				}()
+			}
+
+			var res Result
+
+			err := retry.run(g.Context(), func() error {
+				var ferr error
 
-				errs = append(errs, customerror.New(
+				res, ferr = f(g.Context(), items[i])
+
+				return ferr
+			})
+			if err != nil {
+				return customerror.New(
 					fmt.Sprintf("failed to map %v", items[i]),
 					customerror.WithError(err),
-				))
+				)
+			}
 
-				return
+			// A result that only arrives once the context is done - e.g. the
+			// parent context timed out while `f` was running - isn't collected.
+			if g.Context().Err() != nil {
+				return nil
 			}
 
 			// Check if result i exists
 			if len(results) <= i {
-				errMutex.Lock()
-				defer errMutex.Unlock()
-
-				errs = append(errs, customerror.New(
+				return customerror.New(
 					fmt.Sprintf("failed to map %v", items[i]),
 					customerror.WithError(fmt.Errorf("result index %v out of range", i)),
-				))
-
-				return
+				)
 			}
 
-			// resMutex.Lock()
-			// defer resMutex.Unlock()
-
 			if o.Limit > 0 {
 				if atomic.LoadUint64(&resultTracker) > uint64(o.Limit) {
-					return
+					return nil
 				}
 			}
 
 			results[i] = res
 
 			atomic.AddUint64(&resultTracker, 1)
-		}(i)
-	}
 
-	wg.Wait()
-
-	if len(errs) > 0 {
-		return RemoveZeroValues(o.RemoveZeroValues, results), errs
+			return nil
+		})
 	}
 
-	return RemoveZeroValues(o.RemoveZeroValues, results), nil
+	errs := g.Wait()
+
+	return RemoveZeroValues(o.RemoveZeroValues, results), errs
 }
 
 // MapM concurrently applies a function `f` to each element in the map `itemMaps`
 // and returns the resulting slice and any errors that occurred. `f` should be
 // of type MapMFunc.
+//
+// If `WithFailFast(true)` is set, the first error returned by `f` cancels the
+// context passed to in-flight and not-yet-started workers, and `MapM` returns
+// as soon as the in-flight workers finish, along with the partial results
+// collected so far.
 func MapM[T any, Result any](
 	ctx context.Context,
 	itemsMap map[string]T,
@@ -221,21 +225,20 @@ func MapM[T any, Result any](
 	o := Option{
 		BatchSize:        runtime.GOMAXPROCS(0),
 		RemoveZeroValues: true,
+		PanicAsError:     true,
 	}
 
 	for _, opt := range opts {
 		o = opt(o)
 	}
 
-	sem := semaphore.NewWeighted(int64(o.BatchSize))
-
-	wg := &sync.WaitGroup{}
+	g := newGroup(ctx, o.BatchSize, o.FailFast)
 
-	results := []Result{}
+	retry := o.retryPolicy()
 
 	var (
-		errs     []error
-		errMutex sync.Mutex
+		resultsMu sync.Mutex
+		results   = []Result{}
 
 		resultTracker uint64 = 1
 	)
@@ -249,63 +252,71 @@ func MapM[T any, Result any](
 		}
 
 		// Context error handling.
-		if ctx.Err() != nil {
-			errs = append(errs, customerror.New(fmt.Sprintf(`context errored before mapping "%v"`, key)))
+		if g.Context().Err() != nil {
+			g.AddErr(customerror.New(fmt.Sprintf(`context errored before mapping "%v"`, key)))
 
-			return RemoveZeroValues(o.RemoveZeroValues, results), errs
+			break
 		}
 
 		// Semaphore handling.
-		if err := sem.Acquire(ctx, 1); err != nil {
-			errs = append(errs, customerror.New(fmt.Sprintf(`context timeout before mapping "%v"`, key)))
+		if err := g.Acquire(); err != nil {
+			g.AddErr(customerror.New(fmt.Sprintf(`context timeout before mapping "%v"`, key)))
 
-			return RemoveZeroValues(o.RemoveZeroValues, results), errs
+			break
 		}
 
 		//////
 		// Loop of items.
 		//////
 
-		wg.Add(1)
+		key, item := key, item
 
-		go func(k string, i T) {
-			defer sem.Release(1)
-			defer wg.Done()
+		g.Go(func() error {
+			if o.PanicAsError {
+				defer func() {
+					if r := recover(); r != nil {
+						err, _ := newPanicError(key, r)
 
-			res, err := f(ctx, k, i)
-			if err != nil {
-				errMutex.Lock()
-				defer errMutex.Unlock()
+						g.AddErr(err)
+					}
+				}()
+			}
 
-				errs = append(errs, customerror.New(
-					fmt.Sprintf("failed to map %v", k),
-					customerror.WithError(err),
-				))
+			var res Result
+
+			err := retry.run(g.Context(), func() error {
+				var ferr error
 
-				return
+				res, ferr = f(g.Context(), key, item)
+
+				return ferr
+			})
+			if err != nil {
+				return customerror.New(
+					fmt.Sprintf("failed to map %v", key),
+					customerror.WithError(err),
+				)
 			}
 
 			// Limit feature.
 			if o.Limit > 0 {
 				if atomic.LoadUint64(&resultTracker) > uint64(o.Limit) {
-					return
+					return nil
 				}
 			}
 
-			errMutex.Lock()
-			defer errMutex.Unlock()
+			resultsMu.Lock()
+			defer resultsMu.Unlock()
 
 			results = append(results, res)
 
 			atomic.AddUint64(&resultTracker, 1)
-		}(key, item)
-	}
 
-	wg.Wait()
-
-	if len(errs) > 0 {
-		return RemoveZeroValues(o.RemoveZeroValues, results), errs
+			return nil
+		})
 	}
 
-	return RemoveZeroValues(o.RemoveZeroValues, results), nil
+	errs := g.Wait()
+
+	return RemoveZeroValues(o.RemoveZeroValues, results), errs
 }
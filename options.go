@@ -1,6 +1,9 @@
 package concurrentloop
 
-import "time"
+import (
+	"math/rand/v2"
+	"time"
+)
 
 //////
 // Consts, vars and types.
@@ -28,6 +31,49 @@ type Option struct {
 
 	// RemoveZeroValues indicates whether to remove zero values from the results.
 	RemoveZeroValues bool
+
+	// PanicAsError indicates whether a panic inside the user-supplied function
+	// should be recovered and converted into an error for the affected
+	// index/key instead of crashing the whole program. Defaults to true.
+	PanicAsError bool
+
+	// FailFast indicates whether the loop should stop scheduling new work as
+	// soon as `f` returns the first non-nil error, cancelling the context
+	// passed down to in-flight and not-yet-started workers.
+	FailFast bool
+
+	// RetryMaxAttempts is the total number of times `f` is called for a given
+	// item before its error is given up on and added to `Errors`. A value of
+	// 0 or 1 disables retrying.
+	RetryMaxAttempts int
+
+	// RetryInitialDelay is the backoff delay used before the first retry.
+	RetryInitialDelay time.Duration
+
+	// RetryMaxDelay caps the computed backoff delay. A value of 0 means no
+	// cap.
+	RetryMaxDelay time.Duration
+
+	// RetryMultiplier is applied to the backoff delay after each attempt.
+	RetryMultiplier float64
+
+	// RetryJitter is the fraction, in `[0, 1]`, of the computed backoff delay
+	// that's added or subtracted at random to avoid retry storms.
+	RetryJitter float64
+
+	// RetryClassifier decides whether an error returned by `f` is worth
+	// retrying. When nil, every non-nil error is considered retriable.
+	RetryClassifier RetryClassifier
+
+	// Clock is used for every sleep performed by `WithRandomDelayTime` and
+	// `WithRetry`. Defaults to a standard-library-backed clock; inject a fake
+	// one in tests to advance time instantly.
+	Clock Clock
+
+	// RandSource is the source of randomness backing `WithRandomDelayTime`
+	// and retry jitter. Defaults to a non-deterministic `math/rand/v2`-backed
+	// source; set it, or use `WithSeed`, for reproducible runs.
+	RandSource rand.Source
 }
 
 //////
@@ -61,6 +107,62 @@ func WithLimit(limit int) Func {
 	}
 }
 
+// WithPanicAsError sets whether a panic inside the user-supplied function is
+// recovered and reported as an error instead of crashing the program. It
+// defaults to `true`; pass `false` to restore the old crash-on-panic
+// behavior.
+func WithPanicAsError(panicAsError bool) Func {
+	return func(o Option) Option {
+		o.PanicAsError = panicAsError
+
+		return o
+	}
+}
+
+// WithFailFast sets whether the loop stops scheduling new work as soon as
+// the first error is returned by `f`, cancelling the context shared with
+// in-flight workers and returning the partial results collected so far
+// alongside the triggering error.
+func WithFailFast(failFast bool) Func {
+	return func(o Option) Option {
+		o.FailFast = failFast
+
+		return o
+	}
+}
+
+// WithRetry enables per-item retrying with exponential backoff and jitter.
+// On a retriable error, a worker waits for `min(max, initial * multiplier^attempt)`
+// plus uniform jitter in `[-jitter, +jitter]` of that delay, then re-invokes
+// `f`. Only after `maxAttempts` total attempts is the error added to
+// `Errors`. The batch slot held for the item is kept across retries, so
+// retries still count against `WithBatchSize`.
+func WithRetry(maxAttempts int, initial, max time.Duration, multiplier float64, jitter float64) Func {
+	return func(o Option) Option {
+		o.RetryMaxAttempts = maxAttempts
+
+		o.RetryInitialDelay = initial
+
+		o.RetryMaxDelay = max
+
+		o.RetryMultiplier = multiplier
+
+		o.RetryJitter = jitter
+
+		return o
+	}
+}
+
+// WithRetryClassifier sets the function used to decide which errors returned
+// by `f` are worth retrying. When not set, every non-nil error is retried.
+func WithRetryClassifier(classifier RetryClassifier) Func {
+	return func(o Option) Option {
+		o.RetryClassifier = classifier
+
+		return o
+	}
+}
+
 // WithRandomDelayTime sets the random delay time between each iteration.
 func WithRandomDelayTime(min, max int, d time.Duration) Func {
 	return func(o Option) Option {
@@ -73,3 +175,36 @@ func WithRandomDelayTime(min, max int, d time.Duration) Func {
 		return o
 	}
 }
+
+// WithClock sets the `Clock` used for every sleep performed by
+// `WithRandomDelayTime` and `WithRetry`. Inject a fake clock in tests to
+// advance time instantly and assert delays happen on schedule without real
+// sleeps.
+func WithClock(clock Clock) Func {
+	return func(o Option) Option {
+		o.Clock = clock
+
+		return o
+	}
+}
+
+// WithRandSource sets the source of randomness backing `WithRandomDelayTime`
+// and retry jitter, in place of the default non-deterministic one.
+func WithRandSource(src rand.Source) Func {
+	return func(o Option) Option {
+		o.RandSource = src
+
+		return o
+	}
+}
+
+// WithSeed is a convenience around `WithRandSource` that seeds a
+// `math/rand/v2`-backed source deterministically, making delays and jitter
+// reproducible across runs.
+func WithSeed(seed int64) Func {
+	return func(o Option) Option {
+		o.RandSource = rand.NewPCG(uint64(seed), uint64(seed))
+
+		return o
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The concurrentloop Authors. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+//
+//nolint:exhaustruct
+package concurrentloop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPanicAsError_Map(t *testing.T) {
+	_, errs := Map(context.Background(), []int{1, 2, 3}, func(ctx context.Context, i int) (int, error) {
+		if i == 2 {
+			panic("kaboom")
+		}
+
+		return i, nil
+	})
+
+	assert.ErrorContains(t, errs, "kaboom")
+}
+
+func TestWithPanicAsError_MapM(t *testing.T) {
+	_, errs := MapM(context.Background(), map[string]int{"a": 1, "b": 2}, func(ctx context.Context, key string, item int) (int, error) {
+		if key == "b" {
+			panic("kaboom")
+		}
+
+		return item, nil
+	})
+
+	assert.ErrorContains(t, errs, "kaboom")
+}
+
+func TestWithPanicAsError_Run(t *testing.T) {
+	_, errs := Run(context.Background(), []int{1, 2, 3}, func(ctx context.Context, i int) (int, error) {
+		if i == 2 {
+			panic("kaboom")
+		}
+
+		return i, nil
+	})
+
+	assert.ErrorContains(t, errs, "kaboom")
+}
+
+func TestWithPanicAsError_Execute(t *testing.T) {
+	fns := []ExecuteFunc[int]{
+		func(ctx context.Context) (int, error) { return 1, nil },
+		func(ctx context.Context) (int, error) { panic("kaboom") },
+		func(ctx context.Context) (int, error) { return 3, nil },
+	}
+
+	_, errs := Execute(context.Background(), fns)
+
+	assert.ErrorContains(t, errs, "kaboom")
+}
+
+func TestWithFailFast_Map(t *testing.T) {
+	sl := []int{1, 2, 3, 4, 5}
+
+	_, errs := Map(context.Background(), sl, func(ctx context.Context, i int) (int, error) {
+		if i == 1 {
+			return 0, errors.New("boom")
+		}
+
+		<-ctx.Done()
+
+		return 0, ctx.Err()
+	}, WithBatchSize(len(sl)), WithFailFast(true))
+
+	assert.ErrorContains(t, errs, "boom")
+}
+
+func TestWithFailFast_MapM(t *testing.T) {
+	itemsMap := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	_, errs := MapM(context.Background(), itemsMap, func(ctx context.Context, key string, item int) (int, error) {
+		if key == "a" {
+			return 0, errors.New("boom")
+		}
+
+		<-ctx.Done()
+
+		return 0, ctx.Err()
+	}, WithBatchSize(len(itemsMap)), WithFailFast(true))
+
+	assert.ErrorContains(t, errs, "boom")
+}
+
+func TestWithFailFast_Run(t *testing.T) {
+	sl := []int{1, 2, 3}
+
+	_, errs := Run(context.Background(), sl, func(ctx context.Context, i int) (int, error) {
+		if i == 1 {
+			return 0, errors.New("boom")
+		}
+
+		<-ctx.Done()
+
+		return 0, ctx.Err()
+	}, WithFailFast(true))
+
+	assert.ErrorContains(t, errs, "boom")
+}
+
+func TestWithFailFast_Execute(t *testing.T) {
+	fns := []ExecuteFunc[int]{
+		func(ctx context.Context) (int, error) { return 0, errors.New("boom") },
+		func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+
+			return 0, ctx.Err()
+		},
+	}
+
+	_, errs := Execute(context.Background(), fns, WithFailFast(true))
+
+	assert.ErrorContains(t, errs, "boom")
+}
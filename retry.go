@@ -0,0 +1,134 @@
+// Copyright 2022 The concurrentloop Authors. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package concurrentloop
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// RetryClassifier decides whether an error returned by a worker function is
+// worth retrying. When not set, every non-nil error is considered retriable.
+type RetryClassifier func(err error) bool
+
+// retryPolicy is the resolved retry configuration for a single `Map`, `MapM`,
+// `Run`, or `Execute` call, built from an `Option`.
+type retryPolicy struct {
+	maxAttempts int
+	initial     time.Duration
+	max         time.Duration
+	multiplier  float64
+	jitter      float64
+	classifier  RetryClassifier
+
+	clock Clock
+
+	// random and randomMu are shared by every worker of the `Map`, `MapM`,
+	// `Run`, or `Execute` call this policy belongs to - `*rand.Rand` isn't
+	// safe for concurrent use, so `randomMu` must be held for every access.
+	random   *rand.Rand
+	randomMu *sync.Mutex
+}
+
+//////
+// Methods.
+//////
+
+// enabled reports whether retry support is configured.
+func (p retryPolicy) enabled() bool {
+	return p.maxAttempts > 1
+}
+
+// backoff computes the delay to wait before the retry attempt numbered
+// `attempt` (0-based, i.e. the delay before the 2nd overall attempt is
+// `backoff(0)`), clamped to `p.max` and jittered uniformly within
+// `[-p.jitter, +p.jitter] * delay`.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.initial) * math.Pow(p.multiplier, float64(attempt))
+
+	if p.max > 0 && delay > float64(p.max) {
+		delay = float64(p.max)
+	}
+
+	if p.jitter > 0 {
+		p.randomMu.Lock()
+		r := p.random.Float64()
+		p.randomMu.Unlock()
+
+		delay += delay * p.jitter * (2*r - 1)
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	return time.Duration(delay)
+}
+
+// run invokes `fn`, honoring the retry policy: on a retriable error it sleeps
+// for the computed backoff - respecting `ctx` cancellation - and retries, up
+// to `p.maxAttempts` total attempts. It returns the last error seen once
+// attempts are exhausted, the error isn't retriable, or `ctx` is done.
+func (p retryPolicy) run(ctx context.Context, fn func() error) error {
+	if !p.enabled() {
+		return fn()
+	}
+
+	var err error
+
+	for attempt := 0; attempt < p.maxAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+
+		isRetriable := true
+
+		if p.classifier != nil {
+			isRetriable = p.classifier(err)
+		}
+
+		if !isRetriable || attempt == p.maxAttempts-1 {
+			return err
+		}
+
+		slept := make(chan struct{})
+
+		go func() {
+			p.clock.Sleep(p.backoff(attempt))
+			close(slept)
+		}()
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-slept:
+		}
+	}
+
+	return err
+}
+
+// retryPolicy builds the `retryPolicy` described by `o`.
+func (o Option) retryPolicy() retryPolicy {
+	return retryPolicy{
+		maxAttempts: o.RetryMaxAttempts,
+		initial:     o.RetryInitialDelay,
+		max:         o.RetryMaxDelay,
+		multiplier:  o.RetryMultiplier,
+		jitter:      o.RetryJitter,
+		classifier:  o.RetryClassifier,
+		clock:       clockOrDefault(o.Clock),
+		random:      rand.New(randSourceOrDefault(o.RandSource)),
+		randomMu:    &sync.Mutex{},
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The concurrentloop Authors. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+//
+//nolint:exhaustruct
+package concurrentloop
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRetry(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	var attempts int32
+
+	_, errs := Map(context.Background(), []int{1}, func(ctx context.Context, i int) (int, error) {
+		n := atomic.AddInt32(&attempts, 1)
+
+		if n < 3 {
+			return 0, errors.New("transient")
+		}
+
+		return i, nil
+	}, WithRetry(5, time.Second, 10*time.Second, 2, 0), WithClock(clock))
+
+	assert.Nil(t, errs)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+	assert.Len(t, clock.sleeps(), 2)
+	assert.Equal(t, time.Second, clock.sleeps()[0])
+	assert.Equal(t, 2*time.Second, clock.sleeps()[1])
+}
+
+func TestWithRetry_givesUpAfterMaxAttempts(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	var attempts int32
+
+	_, errs := Map(context.Background(), []int{1}, func(ctx context.Context, i int) (int, error) {
+		atomic.AddInt32(&attempts, 1)
+
+		return 0, errors.New("permanent")
+	}, WithRetry(3, time.Millisecond, 0, 2, 0), WithClock(clock))
+
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+	assert.ErrorContains(t, errs, "permanent")
+}
+
+func TestWithRetryClassifier(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	var attempts int32
+
+	_, errs := Map(context.Background(), []int{1}, func(ctx context.Context, i int) (int, error) {
+		atomic.AddInt32(&attempts, 1)
+
+		return 0, errors.New("not retriable")
+	},
+		WithRetry(5, time.Millisecond, 0, 2, 0),
+		WithClock(clock),
+		WithRetryClassifier(func(err error) bool { return false }),
+	)
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts))
+	assert.ErrorContains(t, errs, "not retriable")
+}
+
+func TestWithRetry_MapM(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	var attempts int32
+
+	_, errs := MapM(context.Background(), map[string]int{"a": 1}, func(ctx context.Context, key string, item int) (int, error) {
+		n := atomic.AddInt32(&attempts, 1)
+
+		if n < 3 {
+			return 0, errors.New("transient")
+		}
+
+		return item, nil
+	}, WithRetry(5, time.Millisecond, 0, 2, 0), WithClock(clock))
+
+	assert.Nil(t, errs)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
+
+func TestWithRetry_Run(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	var attempts int32
+
+	_, errs := Run(context.Background(), []int{1}, func(ctx context.Context, i int) (int, error) {
+		n := atomic.AddInt32(&attempts, 1)
+
+		if n < 3 {
+			return 0, errors.New("transient")
+		}
+
+		return i, nil
+	}, WithRetry(5, time.Millisecond, 0, 2, 0), WithClock(clock))
+
+	assert.Nil(t, errs)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
+
+func TestWithRetry_Execute(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	var attempts int32
+
+	fns := []ExecuteFunc[int]{
+		func(ctx context.Context) (int, error) {
+			n := atomic.AddInt32(&attempts, 1)
+
+			if n < 3 {
+				return 0, errors.New("transient")
+			}
+
+			return 1, nil
+		},
+	}
+
+	_, errs := Execute(context.Background(), fns, WithRetry(5, time.Millisecond, 0, 2, 0), WithClock(clock))
+
+	assert.Nil(t, errs)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
@@ -4,6 +4,13 @@
 
 package concurrentloop
 
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/thalesfsp/customerror"
+)
+
 //////
 // Vars, consts, and types.
 //////
@@ -16,6 +23,29 @@ type ResultCh[T any] struct {
 	Error  error
 	Index  int
 	Output T
+
+	// Panic carries the raw value recovered from a panic inside the
+	// user-supplied function, if any.
+	Panic any
+
+	// Stack carries the stack trace captured at the time of the panic, if
+	// any.
+	Stack []byte
+}
+
+//////
+// Helpers.
+//////
+
+// newPanicError translates a recovered panic value `r` into a `customerror`,
+// and captures the current stack trace.
+//
+// NOTE: The caller must call `recover()` itself, directly inside its own
+// deferred function, before calling this - `recover` only stops a panic
+// when called directly by the deferred function, not when called by a
+// function the deferred function merely invokes.
+func newPanicError(label string, r any) (err error, stack []byte) {
+	return customerror.New(fmt.Sprintf("recovered from panic while processing %q: %v", label, r)), debug.Stack()
 }
 
 // Flatten2D takes a 2D slice and returns a 1D slice containing all the elements.
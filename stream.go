@@ -0,0 +1,283 @@
+// Copyright 2022 The concurrentloop Authors. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package concurrentloop
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Exported functionalities.
+//////
+
+// MapStream concurrently applies `f` to each item received on `in`, and
+// emits each result on the returned channel as soon as its worker finishes -
+// results may arrive out of input order. It's meant for producers that can't,
+// or shouldn't, be materialized into a slice up-front, e.g. items piped in
+// from another stage of a pipeline.
+//
+// It honors `WithBatchSize`, `WithLimit`, context cancellation, and the
+// panic/fail-fast/retry options. Both returned channels are closed exactly
+// once, once `in` is drained or `ctx` is done.
+func MapStream[T any, Result any](
+	ctx context.Context,
+	in <-chan T,
+	f MapFunc[T, Result],
+	opts ...Func,
+) (<-chan ResultCh[Result], <-chan Errors) {
+	o := Option{
+		BatchSize:    runtime.NumCPU(),
+		PanicAsError: true,
+	}
+
+	for _, opt := range opts {
+		o = opt(o)
+	}
+
+	g := newGroup(ctx, o.BatchSize, o.FailFast)
+	retry := o.retryPolicy()
+
+	resultsCh := make(chan ResultCh[Result])
+	errsCh := make(chan Errors, 1)
+
+	go func() {
+		defer close(resultsCh)
+
+		var count uint64 = 1
+
+	loop:
+		for {
+			select {
+			case <-g.Context().Done():
+				break loop
+			case item, ok := <-in:
+				if !ok {
+					break loop
+				}
+
+				if o.Limit > 0 && atomic.LoadUint64(&count) > uint64(o.Limit) {
+					break loop
+				}
+
+				if err := g.Acquire(); err != nil {
+					g.AddErr(customerror.New(fmt.Sprintf(`context done while waiting for a worker slot for "%v"`, item)))
+
+					break loop
+				}
+
+				g.Go(func() error {
+					if o.PanicAsError {
+						defer func() {
+							if r := recover(); r != nil {
+								err, stack := newPanicError(fmt.Sprintf("%v", item), r)
+
+								resultsCh <- ResultCh[Result]{Error: err, Panic: r, Stack: stack}
+
+								g.AddErr(err)
+							}
+						}()
+					}
+
+					var res Result
+
+					err := retry.run(g.Context(), func() error {
+						var ferr error
+
+						res, ferr = f(g.Context(), item)
+
+						return ferr
+					})
+					if err != nil {
+						werr := customerror.New(
+							fmt.Sprintf("failed to map %v", item),
+							customerror.WithError(err),
+						)
+
+						resultsCh <- ResultCh[Result]{Error: werr}
+
+						return werr
+					}
+
+					if o.Limit > 0 && atomic.LoadUint64(&count) > uint64(o.Limit) {
+						return nil
+					}
+
+					atomic.AddUint64(&count, 1)
+
+					resultsCh <- ResultCh[Result]{Output: res}
+
+					return nil
+				})
+			}
+		}
+
+		errsCh <- g.Wait()
+
+		close(errsCh)
+	}()
+
+	return resultsCh, errsCh
+}
+
+// MapOrdered behaves like `MapStream`, except results are re-ordered, using
+// a small buffer keyed by the monotonic index each item was read from `in`
+// at, so they're emitted on the returned channel in input order - even when
+// `WithBatchSize` allows more than one worker to run at a time.
+func MapOrdered[T any, Result any](
+	ctx context.Context,
+	in <-chan T,
+	f MapFunc[T, Result],
+	opts ...Func,
+) (<-chan ResultCh[Result], <-chan Errors) {
+	o := Option{
+		BatchSize:    runtime.NumCPU(),
+		PanicAsError: true,
+	}
+
+	for _, opt := range opts {
+		o = opt(o)
+	}
+
+	g := newGroup(ctx, o.BatchSize, o.FailFast)
+	retry := o.retryPolicy()
+
+	type indexedResult struct {
+		index int
+		res   ResultCh[Result]
+
+		// skip marks an index whose result is dropped, e.g. over `WithLimit`,
+		// so the re-order buffer moves past it without emitting anything.
+		skip bool
+	}
+
+	rawCh := make(chan indexedResult)
+	resultsCh := make(chan ResultCh[Result])
+	errsCh := make(chan Errors, 1)
+
+	// Scheduling loop: reads `in`, tags each item with a monotonic index, and
+	// runs it through a worker, same as `MapStream`.
+	go func() {
+		defer close(rawCh)
+
+		var (
+			count uint64 = 1
+			index int
+		)
+
+	loop:
+		for {
+			select {
+			case <-g.Context().Done():
+				break loop
+			case item, ok := <-in:
+				if !ok {
+					break loop
+				}
+
+				if o.Limit > 0 && atomic.LoadUint64(&count) > uint64(o.Limit) {
+					break loop
+				}
+
+				if err := g.Acquire(); err != nil {
+					g.AddErr(customerror.New(fmt.Sprintf(`context done while waiting for a worker slot for "%v"`, item)))
+
+					break loop
+				}
+
+				item, idx := item, index
+				index++
+
+				g.Go(func() error {
+					if o.PanicAsError {
+						defer func() {
+							if r := recover(); r != nil {
+								err, stack := newPanicError(fmt.Sprintf("%v", item), r)
+
+								rawCh <- indexedResult{
+									index: idx,
+									res:   ResultCh[Result]{Error: err, Panic: r, Stack: stack},
+								}
+
+								g.AddErr(err)
+							}
+						}()
+					}
+
+					var res Result
+
+					err := retry.run(g.Context(), func() error {
+						var ferr error
+
+						res, ferr = f(g.Context(), item)
+
+						return ferr
+					})
+					if err != nil {
+						werr := customerror.New(
+							fmt.Sprintf("failed to map %v", item),
+							customerror.WithError(err),
+						)
+
+						rawCh <- indexedResult{index: idx, res: ResultCh[Result]{Error: werr}}
+
+						return werr
+					}
+
+					if o.Limit > 0 && atomic.LoadUint64(&count) > uint64(o.Limit) {
+						rawCh <- indexedResult{index: idx, skip: true}
+
+						return nil
+					}
+
+					atomic.AddUint64(&count, 1)
+
+					rawCh <- indexedResult{index: idx, res: ResultCh[Result]{Output: res}}
+
+					return nil
+				})
+			}
+		}
+
+		errsCh <- g.Wait()
+
+		close(errsCh)
+	}()
+
+	// Re-order buffer: holds out-of-order results until the one matching
+	// `next` shows up, then flushes everything it can in a row.
+	go func() {
+		defer close(resultsCh)
+
+		buffer := make(map[int]indexedResult)
+
+		next := 0
+
+		for r := range rawCh {
+			buffer[r.index] = r
+
+			for {
+				res, ok := buffer[next]
+				if !ok {
+					break
+				}
+
+				if !res.skip {
+					resultsCh <- res.res
+				}
+
+				delete(buffer, next)
+
+				next++
+			}
+		}
+	}()
+
+	return resultsCh, errsCh
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 The concurrentloop Authors. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+//
+//nolint:exhaustruct
+package concurrentloop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func drainStream[T any](in chan<- T, items []T) {
+	defer close(in)
+
+	for _, item := range items {
+		in <- item
+	}
+}
+
+func TestMapStream(t *testing.T) {
+	in := make(chan int)
+
+	go drainStream(in, []int{1, 2, 3, 4, 5})
+
+	resultsCh, errsCh := MapStream(context.Background(), in, func(ctx context.Context, i int) (int, error) {
+		return i * 2, nil
+	})
+
+	var got []int
+
+	for r := range resultsCh {
+		assert.NoError(t, r.Error)
+
+		got = append(got, r.Output)
+	}
+
+	assert.Nil(t, <-errsCh)
+	assert.ElementsMatch(t, []int{2, 4, 6, 8, 10}, got)
+}
+
+func TestMapStream_error(t *testing.T) {
+	in := make(chan int)
+
+	go drainStream(in, []int{1, 2, 3})
+
+	resultsCh, errsCh := MapStream(context.Background(), in, func(ctx context.Context, i int) (int, error) {
+		if i == 2 {
+			return 0, errors.New("boom")
+		}
+
+		return i, nil
+	})
+
+	var errCount int
+
+	for r := range resultsCh {
+		if r.Error != nil {
+			errCount++
+		}
+	}
+
+	assert.Equal(t, 1, errCount)
+	assert.ErrorContains(t, <-errsCh, "boom")
+}
+
+func TestMapStream_panic(t *testing.T) {
+	in := make(chan int)
+
+	go drainStream(in, []int{1, 2, 3})
+
+	resultsCh, errsCh := MapStream(context.Background(), in, func(ctx context.Context, i int) (int, error) {
+		if i == 2 {
+			panic("kaboom")
+		}
+
+		return i, nil
+	})
+
+	var panics int
+
+	for r := range resultsCh {
+		if r.Panic != nil {
+			panics++
+
+			assert.Equal(t, "kaboom", r.Panic)
+			assert.NotEmpty(t, r.Stack)
+		}
+	}
+
+	assert.Equal(t, 1, panics)
+	assert.ErrorContains(t, <-errsCh, "kaboom")
+}
+
+func TestMapOrdered(t *testing.T) {
+	in := make(chan int)
+
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	go drainStream(in, items)
+
+	resultsCh, errsCh := MapOrdered(context.Background(), in, func(ctx context.Context, i int) (int, error) {
+		return i * 2, nil
+	}, WithBatchSize(4))
+
+	var got []int
+
+	for r := range resultsCh {
+		assert.NoError(t, r.Error)
+
+		got = append(got, r.Output)
+	}
+
+	assert.Nil(t, <-errsCh)
+	assert.Equal(t, []int{2, 4, 6, 8, 10, 12, 14, 16, 18, 20}, got)
+}
+
+func TestMapOrdered_panicAddsError(t *testing.T) {
+	in := make(chan int)
+
+	go drainStream(in, []int{1, 2, 3})
+
+	resultsCh, errsCh := MapOrdered(context.Background(), in, func(ctx context.Context, i int) (int, error) {
+		if i == 2 {
+			panic("kaboom")
+		}
+
+		return i, nil
+	})
+
+	for range resultsCh {
+	}
+
+	assert.ErrorContains(t, <-errsCh, "kaboom")
+}